@@ -0,0 +1,45 @@
+package raft
+
+import "time"
+
+// Apply is used to apply a command to the FSM in a highly consistent
+// manner. This returns a future that can be used to wait on the
+// application.
+func (r *Raft) Apply(cmd []byte, timeout time.Duration) ApplyFuture {
+	return r.raftApply(LogCommand, cmd, nil, timeout)
+}
+
+// raftApply is a helper used by Apply and ApplyChunked to dispatch a
+// single log entry of type t, optionally carrying an Extensions
+// payload such as chunkInfo, through the normal commit pipeline.
+func (r *Raft) raftApply(t LogType, cmd []byte, extensions []byte, timeout time.Duration) ApplyFuture {
+	// A LeadershipTransfer in progress is racing the target to catch up
+	// to our current last log index; accepting more writes here would
+	// keep moving that target and could stall the handoff indefinitely.
+	if r.getLeadershipTransferInProgress() {
+		return errorFuture{ErrLeadershipTransferInProgress}
+	}
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		timer = time.After(timeout)
+	}
+
+	logFuture := &logFuture{
+		log: Log{
+			Type:       t,
+			Data:       cmd,
+			Extensions: extensions,
+		},
+	}
+	logFuture.init()
+
+	select {
+	case <-timer:
+		return errorFuture{ErrEnqueueTimeout}
+	case <-r.shutdownCh:
+		return errorFuture{ErrRaftShutdown}
+	case r.applyCh <- logFuture:
+		return logFuture
+	}
+}
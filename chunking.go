@@ -0,0 +1,328 @@
+package raft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-uuid"
+)
+
+// LogChunkBuffer marks a Log written by ChunkingFSM's durable side
+// table. It is local to a single follower's LogStore, is never produced
+// by the leader, and never reaches the user FSM.
+const LogChunkBuffer LogType = 100
+
+// chunkBufferCatalogIndex is the fixed LogStore index ChunkingFSM uses
+// to durably persist every in-flight chunk buffer as a single row. Real
+// log indexes are assigned monotonically starting at 1, so this
+// sentinel, chosen from the top of the uint64 range, never collides
+// with an entry the leader could actually produce.
+const chunkBufferCatalogIndex uint64 = math.MaxUint64
+
+// chunkInfo is carried in a Log's Extensions field to let a ChunkingFSM
+// reassemble a command that was split across multiple log entries by
+// the leader. replicateTo and pipelineSend ship these logs exactly like
+// any other entry, so no transport or protocol change is required.
+type chunkInfo struct {
+	OpID      string
+	SeqNo     uint32
+	NumChunks uint32
+	IsFinal   bool
+}
+
+// chunkBuffer accumulates the chunks for a single in-flight OpID until
+// the final chunk arrives. Its fields are exported so it round-trips
+// through encodeMsgPack/decodeMsgPack, both for the durable side table
+// and for chunkingSnapshot.
+type chunkBuffer struct {
+	Chunks map[uint32][]byte
+	Total  uint32
+}
+
+// ChunkingFSM wraps a user FSM and transparently reassembles commands
+// that the leader split into chunks because they exceeded MaxChunkSize.
+// Unchunked entries (no chunkInfo in Extensions) are passed straight
+// through to the wrapped FSM, so mixing chunked and unchunked entries
+// in the same log is safe and is how we support migrating an existing
+// cluster onto chunking without a flag day.
+type ChunkingFSM struct {
+	fsm FSM
+
+	// store durably persists in-flight chunk buffers, keyed by OpID in a
+	// single catalog row, so a follower that restarts after receiving 9
+	// of 10 chunks for an OpID doesn't lose progress and force the
+	// leader to resend from the start.
+	store LogStore
+
+	mu      sync.Mutex
+	buffers map[string]*chunkBuffer
+}
+
+// NewChunkingFSM wraps fsm so that Apply can receive logs chunked by a
+// leader-side Apply path using MaxChunkSize. store is used to durably
+// buffer partially received chunks across restarts; any buffers left
+// over from before the restart are loaded back in immediately so a
+// resumed transfer doesn't have to start over.
+func NewChunkingFSM(fsm FSM, store LogStore) (*ChunkingFSM, error) {
+	c := &ChunkingFSM{
+		fsm:     fsm,
+		store:   store,
+		buffers: make(map[string]*chunkBuffer),
+	}
+	if err := c.loadBuffers(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadBuffers replays the durable catalog row into memory. A LogStore
+// that has never seen a chunk buffer (including one with no LogStore at
+// all) leaves buffers empty, which is indistinguishable from "nothing
+// in flight".
+func (c *ChunkingFSM) loadBuffers() error {
+	if c.store == nil {
+		return nil
+	}
+	var l Log
+	if err := c.store.GetLog(chunkBufferCatalogIndex, &l); err != nil {
+		if err == ErrLogNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to load chunk buffer catalog: %v", err)
+	}
+	var buffers map[string]*chunkBuffer
+	if err := decodeMsgPack(l.Data, &buffers); err != nil {
+		return fmt.Errorf("failed to decode chunk buffer catalog: %v", err)
+	}
+	c.buffers = buffers
+	return nil
+}
+
+// Apply intercepts chunked logs, buffering them until the final chunk
+// arrives, at which point the reassembled command is handed to the
+// wrapped FSM and its result is returned to the caller's ApplyFuture.
+// Unchunked logs pass through unmodified.
+func (c *ChunkingFSM) Apply(l *Log) interface{} {
+	info, ok := decodeChunkInfo(l.Extensions)
+	if !ok {
+		return c.fsm.Apply(l)
+	}
+
+	c.mu.Lock()
+	buf, ok := c.buffers[info.OpID]
+	if !ok {
+		buf = &chunkBuffer{Chunks: make(map[uint32][]byte), Total: info.NumChunks}
+		c.buffers[info.OpID] = buf
+	}
+	buf.Chunks[info.SeqNo] = l.Data
+
+	final := info.IsFinal && uint32(len(buf.Chunks)) >= buf.Total
+	if final {
+		delete(c.buffers, info.OpID)
+	}
+	if err := c.persistCatalog(); err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("failed to persist chunk buffer for op %s: %v", info.OpID, err)
+	}
+	c.mu.Unlock()
+
+	if !final {
+		metrics.IncrCounter([]string{"raft", "chunking", "chunk"}, 1)
+		return nil
+	}
+
+	cmd, err := reassemble(buf)
+	if err != nil {
+		return err
+	}
+	metrics.IncrCounter([]string{"raft", "chunking", "reassembled"}, 1)
+	return c.fsm.Apply(&Log{
+		Index:      l.Index,
+		Term:       l.Term,
+		Type:       l.Type,
+		Data:       cmd,
+		Extensions: nil,
+	})
+}
+
+// Snapshot persists the wrapped FSM's snapshot alongside any chunk
+// buffers that are still in flight, so a follower that is snapshotted
+// mid-transfer can resume reassembly after a restore.
+func (c *ChunkingFSM) Snapshot() (FSMSnapshot, error) {
+	inner, err := c.fsm.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	buffers := make(map[string]*chunkBuffer, len(c.buffers))
+	for k, v := range c.buffers {
+		buffers[k] = v
+	}
+	c.mu.Unlock()
+	return &chunkingSnapshot{inner: inner, buffers: buffers}, nil
+}
+
+// Restore rehydrates the wrapped FSM from a snapshot produced by
+// Snapshot, along with any chunk buffers that were still in flight when
+// the snapshot was taken, so reassembly can continue once the leader
+// resends the rest of the command's chunks.
+func (c *ChunkingFSM) Restore(snap io.ReadCloser) error {
+	var hdr [8]byte
+	if _, err := io.ReadFull(snap, hdr[:]); err != nil {
+		return fmt.Errorf("failed to read chunk buffer header: %v", err)
+	}
+	encoded := make([]byte, binary.BigEndian.Uint64(hdr[:]))
+	if _, err := io.ReadFull(snap, encoded); err != nil {
+		return fmt.Errorf("failed to read chunk buffers: %v", err)
+	}
+	var buffers map[string]*chunkBuffer
+	if len(encoded) > 0 {
+		if err := decodeMsgPack(encoded, &buffers); err != nil {
+			return fmt.Errorf("failed to decode chunk buffers: %v", err)
+		}
+	}
+
+	if err := c.fsm.Restore(snap); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.buffers = buffers
+	if c.buffers == nil {
+		c.buffers = make(map[string]*chunkBuffer)
+	}
+	err := c.persistCatalog()
+	c.mu.Unlock()
+	return err
+}
+
+// persistCatalog writes every in-flight chunk buffer as a single row
+// keyed at chunkBufferCatalogIndex, keeping concurrently in-flight
+// OpIDs from clobbering each other the way a shared constant index per
+// buffer would. c.mu must be held by the caller.
+func (c *ChunkingFSM) persistCatalog() error {
+	if c.store == nil {
+		return nil
+	}
+	data, err := encodeMsgPack(c.buffers)
+	if err != nil {
+		return err
+	}
+	return c.store.StoreLog(&Log{
+		Index: chunkBufferCatalogIndex,
+		Type:  LogChunkBuffer,
+		Data:  data,
+	})
+}
+
+func reassemble(buf *chunkBuffer) ([]byte, error) {
+	var out bytes.Buffer
+	for i := uint32(0); i < buf.Total; i++ {
+		chunk, ok := buf.Chunks[i]
+		if !ok {
+			return nil, fmt.Errorf("missing chunk %d of %d", i, buf.Total)
+		}
+		out.Write(chunk)
+	}
+	return out.Bytes(), nil
+}
+
+// chunkingSnapshot adapts an inner FSMSnapshot to also serialize any
+// chunk buffers that hadn't been fully reassembled yet. It frames the
+// encoded buffers with a fixed 8-byte big-endian length prefix ahead of
+// the inner FSM's own bytes, so Restore can split them back apart
+// without the inner FSM needing to know chunking exists.
+type chunkingSnapshot struct {
+	inner   FSMSnapshot
+	buffers map[string]*chunkBuffer
+}
+
+func (c *chunkingSnapshot) Persist(sink SnapshotSink) error {
+	encoded, err := encodeMsgPack(c.buffers)
+	if err != nil {
+		return err
+	}
+	var hdr [8]byte
+	binary.BigEndian.PutUint64(hdr[:], uint64(len(encoded)))
+	if _, err := sink.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := sink.Write(encoded); err != nil {
+		return err
+	}
+	return c.inner.Persist(sink)
+}
+
+func (c *chunkingSnapshot) Release() {
+	c.inner.Release()
+}
+
+// ApplyChunked behaves like Apply, except that commands larger than
+// MaxChunkSize are transparently split into a sequence of smaller logs
+// tagged with a chunkInfo Extensions payload, so that they can cross a
+// transport or MaxAppendEntries limit that wouldn't tolerate the whole
+// command as one entry. Commands at or under MaxChunkSize are applied
+// exactly like Apply, with no Extensions set, so a cluster can migrate
+// onto chunking without rewriting existing entries.
+//
+// The returned future resolves to the user FSM's result for the final
+// chunk once the follower has reassembled and applied the command; the
+// futures for the earlier chunks resolve to nil.
+func (r *Raft) ApplyChunked(cmd []byte, timeout time.Duration) ApplyFuture {
+	if r.conf.MaxChunkSize == 0 || len(cmd) <= r.conf.MaxChunkSize {
+		return r.Apply(cmd, timeout)
+	}
+
+	opID, err := uuid.GenerateUUID()
+	if err != nil {
+		return errorFuture{fmt.Errorf("failed to generate chunking op id: %v", err)}
+	}
+
+	chunkSize := r.conf.MaxChunkSize
+	numChunks := uint32((len(cmd) + chunkSize - 1) / chunkSize)
+
+	var last ApplyFuture
+	for seq := uint32(0); seq < numChunks; seq++ {
+		start := int(seq) * chunkSize
+		end := start + chunkSize
+		if end > len(cmd) {
+			end = len(cmd)
+		}
+		ext, err := encodeMsgPack(&chunkInfo{
+			OpID:      opID,
+			SeqNo:     seq,
+			NumChunks: numChunks,
+			IsFinal:   seq == numChunks-1,
+		})
+		if err != nil {
+			return errorFuture{fmt.Errorf("failed to encode chunk %d of op %s: %v", seq, opID, err)}
+		}
+		last = r.raftApply(LogCommand, cmd[start:end], ext, timeout)
+		// Stop shipping the rest of this op the moment one chunk fails:
+		// the follower's ChunkingFSM buffer for opID only ever completes
+		// once every chunk including the final one arrives, so sending
+		// later chunks of an op that already lost one would just leave
+		// that buffer sitting around forever with no GC to reclaim it.
+		if err := last.Error(); err != nil {
+			return last
+		}
+	}
+	return last
+}
+
+func decodeChunkInfo(ext []byte) (*chunkInfo, bool) {
+	if len(ext) == 0 {
+		return nil, false
+	}
+	var info chunkInfo
+	if err := decodeMsgPack(ext, &info); err != nil {
+		return nil, false
+	}
+	return &info, true
+}
@@ -0,0 +1,15 @@
+package raft
+
+// TimeoutNowRequest is the command sent by a leader performing a
+// LeadershipTransfer to tell an already-caught-up target to skip its
+// randomized election timeout and start an election immediately.
+type TimeoutNowRequest struct {
+	Term   uint64
+	Leader []byte
+}
+
+// TimeoutNowResponse is the response to a TimeoutNowRequest.
+type TimeoutNowResponse struct {
+	Term    uint64
+	Success bool
+}
@@ -0,0 +1,110 @@
+package raft
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config provides any necessary configuration for the Raft server.
+type Config struct {
+	// HeartbeatTimeout specifies the time in follower state without
+	// contact from a leader before we attempt an election.
+	HeartbeatTimeout time.Duration
+
+	// ElectionTimeout specifies the time in candidate state without
+	// a leader before we attempt an election.
+	ElectionTimeout time.Duration
+
+	// CommitTimeout controls the time without an Apply() operation
+	// before we heartbeat to ensure a timely commit. Due to random
+	// staggering, may be delayed as much as 2x this value.
+	CommitTimeout time.Duration
+
+	// MaxAppendEntries controls the maximum number of append entries
+	// to send at once. We want to strike a balance between efficiency
+	// and avoiding waste if the follower is going to reject because of
+	// an inconsistent log.
+	MaxAppendEntries int
+
+	// SnapshotInterval controls how often we check if we should perform
+	// a snapshot.
+	SnapshotInterval time.Duration
+
+	// SnapshotThreshold controls how many outstanding logs there must be
+	// before we perform a snapshot.
+	SnapshotThreshold uint64
+
+	// LeaderLeaseTimeout is used to control how long the "lease" lasts
+	// for being the leader without being able to contact a quorum
+	// of nodes.
+	LeaderLeaseTimeout time.Duration
+
+	// MaxChunkSize is the largest command ApplyChunked will hand to
+	// raftApply as a single log entry before splitting it into a
+	// sequence of chunkInfo-tagged entries. Zero disables chunking, so
+	// ApplyChunked behaves exactly like Apply.
+	MaxChunkSize int
+
+	// SnapshotChunkSize is the size of each chunk sendLatestSnapshot
+	// reads from the snapshot reader and ships in a single
+	// InstallSnapshot RPC. Smaller chunks bound how much of a transfer
+	// a single RPC failure can cost at the expense of more round trips.
+	SnapshotChunkSize int64
+
+	// MaxInflightSnapshotChunks caps how many InstallSnapshot chunk RPCs
+	// sendLatestSnapshot keeps outstanding at once for a single peer, so
+	// a high-latency link doesn't serialize the whole transfer behind a
+	// round trip per chunk while still bounding memory use.
+	MaxInflightSnapshotChunks int
+
+	// LeadershipTransferTimeout is how long LeadershipTransfer waits for
+	// the target to catch up to our last log index before giving up and
+	// resuming normal operation.
+	LeadershipTransferTimeout time.Duration
+}
+
+// DefaultConfig returns a Config struct with usable defaults.
+func DefaultConfig() *Config {
+	return &Config{
+		HeartbeatTimeout:          1000 * time.Millisecond,
+		ElectionTimeout:           1000 * time.Millisecond,
+		CommitTimeout:             50 * time.Millisecond,
+		MaxAppendEntries:          64,
+		SnapshotInterval:          120 * time.Second,
+		SnapshotThreshold:         8192,
+		LeaderLeaseTimeout:        500 * time.Millisecond,
+		MaxChunkSize:              0,
+		SnapshotChunkSize:         16 * 1024,
+		MaxInflightSnapshotChunks: 4,
+		LeadershipTransferTimeout: 1 * time.Second,
+	}
+}
+
+// ValidateConfig is used to validate a sane configuration.
+func ValidateConfig(config *Config) error {
+	if config.HeartbeatTimeout < 5*time.Millisecond {
+		return fmt.Errorf("heartbeat timeout is too low")
+	}
+	if config.ElectionTimeout < 5*time.Millisecond {
+		return fmt.Errorf("election timeout is too low")
+	}
+	if config.CommitTimeout < time.Millisecond {
+		return fmt.Errorf("commit timeout is too low")
+	}
+	if config.MaxAppendEntries <= 0 {
+		return fmt.Errorf("max append entries must be positive")
+	}
+	if config.MaxChunkSize < 0 {
+		return fmt.Errorf("max chunk size must not be negative")
+	}
+	if config.SnapshotChunkSize <= 0 {
+		return fmt.Errorf("snapshot chunk size must be positive")
+	}
+	if config.MaxInflightSnapshotChunks <= 0 {
+		return fmt.Errorf("max inflight snapshot chunks must be positive")
+	}
+	if config.LeadershipTransferTimeout < 5*time.Millisecond {
+		return fmt.Errorf("leadership transfer timeout is too low")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package raft
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultConfigValidates(t *testing.T) {
+	if err := ValidateConfig(DefaultConfig()); err != nil {
+		t.Fatalf("DefaultConfig() failed validation: %v", err)
+	}
+}
+
+func TestValidateConfig_RejectsLowLeadershipTransferTimeout(t *testing.T) {
+	c := DefaultConfig()
+	c.LeadershipTransferTimeout = time.Millisecond
+	if err := ValidateConfig(c); err == nil {
+		t.Fatal("expected error for too-low LeadershipTransferTimeout")
+	}
+}
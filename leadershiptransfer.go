@@ -0,0 +1,181 @@
+package raft
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// leadershipTransferBurstFactor widens MaxAppendEntries by this factor
+// while a peer is being caught up for a leadership transfer, so the
+// handoff finishes in as few round trips as possible.
+const leadershipTransferBurstFactor = 4
+
+// ErrLeadershipTransferInProgress is returned by Apply (via raftApply)
+// while a LeadershipTransfer is in flight, since accepting more writes
+// would keep moving the target the transfer is racing to catch up to.
+var ErrLeadershipTransferInProgress = errors.New("leadership transfer in progress")
+
+// leadershipTransferFuture is returned by LeadershipTransfer. It
+// resolves once the target has either been handed a TimeoutNow RPC, or
+// the transfer failed or timed out.
+type leadershipTransferFuture struct {
+	errCh chan error
+}
+
+func newLeadershipTransferFuture() *leadershipTransferFuture {
+	return &leadershipTransferFuture{errCh: make(chan error, 1)}
+}
+
+func (f *leadershipTransferFuture) respond(err error) {
+	f.errCh <- err
+}
+
+// Error blocks until the transfer completes and returns its result.
+func (f *leadershipTransferFuture) Error() error {
+	return <-f.errCh
+}
+
+// LeadershipTransfer implements the Raft paper's leadership-transfer
+// extension: new Apply calls are rejected while the transfer is in
+// progress, the target's followerReplication is caught up to our last
+// log index on a priority path, and once it matches we send it
+// TimeoutNow so it starts an election immediately instead of waiting
+// out its normal election timeout. If the target doesn't catch up
+// within LeadershipTransferTimeout, the leader resumes normal operation
+// and the returned future reports an error.
+func (r *Raft) LeadershipTransfer(target net.Addr) Future {
+	if r.State() != Leader {
+		return errorFuture{ErrNotLeader}
+	}
+
+	repl, err := r.getFollowerReplication(target)
+	if err != nil {
+		return errorFuture{err}
+	}
+
+	if !r.beginLeadershipTransfer() {
+		return errorFuture{fmt.Errorf("a leadership transfer is already in progress")}
+	}
+	future := newLeadershipTransferFuture()
+
+	select {
+	case repl.transferCh <- future:
+		return leadershipTransferDeferredFuture{r: r, inner: future}
+	case <-r.shutdownCh:
+		r.endLeadershipTransfer()
+		return errorFuture{ErrRaftShutdown}
+	}
+}
+
+// leadershipTransferDeferredFuture clears the in-progress flag once the
+// inner future resolves, regardless of the outcome, so Apply is
+// unblocked whether the transfer succeeded, failed, or timed out.
+type leadershipTransferDeferredFuture struct {
+	r     *Raft
+	inner *leadershipTransferFuture
+}
+
+func (f leadershipTransferDeferredFuture) Error() error {
+	err := f.inner.Error()
+	f.r.endLeadershipTransfer()
+	return err
+}
+
+// leadershipTransferCatchUp runs on the target's replicate goroutine.
+// It bypasses CommitTimeout and repeatedly nudges triggerCh until the
+// peer's matchIndex reaches our last log index, then sends TimeoutNow.
+func (r *Raft) leadershipTransferCatchUp(s *followerReplication, future *leadershipTransferFuture) {
+	atomic.StoreUint32(&s.transferring, 1)
+	defer atomic.StoreUint32(&s.transferring, 0)
+
+	deadline := time.Now().Add(r.conf.LeadershipTransferTimeout)
+	for {
+		lastIdx := r.getLastLogIndex()
+		if s.matchIndex >= lastIdx {
+			break
+		}
+		if time.Now().After(deadline) {
+			future.respond(fmt.Errorf("leadership transfer to %v timed out catching up", s.peer))
+			return
+		}
+		if stop := r.replicateTo(s, lastIdx); stop {
+			future.respond(fmt.Errorf("lost leadership while transferring to %v", s.peer))
+			return
+		}
+		asyncNotifyCh(s.triggerCh)
+	}
+
+	req := TimeoutNowRequest{
+		Term:   s.currentTerm,
+		Leader: r.trans.EncodePeer(r.localAddr),
+	}
+	var resp TimeoutNowResponse
+	if err := r.trans.TimeoutNow(s.peer, &req, &resp); err != nil {
+		future.respond(fmt.Errorf("failed to send TimeoutNow to %v: %v", s.peer, err))
+		return
+	}
+	future.respond(nil)
+}
+
+// beginLeadershipTransfer atomically claims the in-progress flag,
+// gating raftApply so new Apply calls are rejected for the duration of
+// the transfer. It reports false if another LeadershipTransfer is
+// already running, so two overlapping calls can't let one's completion
+// clear the flag mid-way through the other's catch-up. The flag lives
+// on leaderState, which is reset whenever this node becomes leader, so
+// it can never leak an in-progress transfer across a leadership change.
+func (r *Raft) beginLeadershipTransfer() bool {
+	return atomic.CompareAndSwapUint32(&r.leaderState.transferInProgress, 0, 1)
+}
+
+// endLeadershipTransfer clears the in-progress flag set by a
+// successful beginLeadershipTransfer.
+func (r *Raft) endLeadershipTransfer() {
+	atomic.StoreUint32(&r.leaderState.transferInProgress, 0)
+}
+
+// getLeadershipTransferInProgress reports whether a LeadershipTransfer
+// is currently in flight.
+func (r *Raft) getLeadershipTransferInProgress() bool {
+	return atomic.LoadUint32(&r.leaderState.transferInProgress) == 1
+}
+
+// getFollowerReplication looks up the followerReplication the leader is
+// using to replicate to target.
+func (r *Raft) getFollowerReplication(target net.Addr) (*followerReplication, error) {
+	r.leaderState.replicationStateLock.RLock()
+	defer r.leaderState.replicationStateLock.RUnlock()
+
+	repl, ok := r.leaderState.replState[target.String()]
+	if !ok {
+		return nil, fmt.Errorf("peer %v is not currently being replicated to", target)
+	}
+	return repl, nil
+}
+
+// maxAppendEntries returns the batch size replicateTo and pipelineSend
+// should use for s: the configured MaxAppendEntries, widened while s is
+// the target of an in-progress leadership transfer.
+func (r *Raft) maxAppendEntries(s *followerReplication) int {
+	if atomic.LoadUint32(&s.transferring) == 1 {
+		return r.conf.MaxAppendEntries * leadershipTransferBurstFactor
+	}
+	return r.conf.MaxAppendEntries
+}
+
+// processTimeoutNow handles a TimeoutNowRequest, dispatched by
+// processRPC's *TimeoutNowRequest case. It is the follower-side
+// counterpart of leadershipTransferCatchUp: skip the randomized
+// election timeout and start an election immediately at the leader's
+// request.
+func (r *Raft) processTimeoutNow(rpc RPC, req *TimeoutNowRequest) {
+	resp := &TimeoutNowResponse{
+		Term:    r.getCurrentTerm(),
+		Success: true,
+	}
+	rpc.Respond(resp, nil)
+	r.setState(Candidate)
+}
@@ -1,8 +1,10 @@
 package raft
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -36,6 +38,16 @@ type followerReplication struct {
 
 	failures uint64
 
+	// snapshotOpID and snapshotOffset track a resumable InstallSnapshot
+	// transfer to this peer. snapshotOffset is the byte offset we've
+	// read and dispatched to the follower so far; highestAckedOffset is
+	// the highest contiguous offset the follower has acknowledged as
+	// durably staged. On the next sendLatestSnapshot call for the same
+	// peer we resume from there instead of restarting at byte 0.
+	snapshotOpID       string
+	snapshotOffset     int64
+	highestAckedOffset int64
+
 	notifyCh   chan struct{}
 	notify     []*verifyFuture
 	notifyLock sync.Mutex
@@ -47,6 +59,18 @@ type followerReplication struct {
 	// allowPipeline is used to control it seems like
 	// pipeline replication should be enabled
 	allowPipeline bool
+
+	// transferCh carries a pending LeadershipTransfer request targeting
+	// this peer. replicate's select loop drains it and hands off to
+	// leadershipTransferCatchUp, which bypasses CommitTimeout, nudges
+	// triggerCh continuously, and widens MaxAppendEntries until this
+	// peer matches our last log index, then sends it TimeoutNow.
+	transferCh chan *leadershipTransferFuture
+
+	// transferring is set for the duration of leadershipTransferCatchUp
+	// so that replicateTo and pipelineSend know to use a wider
+	// MaxAppendEntries for this peer to catch it up as fast as possible.
+	transferring uint32
 }
 
 // notifyAll is used to notify all the waiting verify futures
@@ -97,6 +121,8 @@ RPC:
 				r.replicateTo(s, maxIndex)
 			}
 			return
+		case future := <-s.transferCh:
+			r.leadershipTransferCatchUp(s, future)
 		case <-s.triggerCh:
 			shouldStop = r.replicateTo(s, r.getLastLogIndex())
 		case <-randomTimeout(r.conf.CommitTimeout):
@@ -173,9 +199,12 @@ START:
 		req.PrevLogTerm = l.Term
 	}
 
-	// Append up to MaxAppendEntries or up to the lastIndex
-	req.Entries = make([]*Log, 0, r.conf.MaxAppendEntries)
-	maxIndex = min(s.nextIndex+uint64(r.conf.MaxAppendEntries)-1, lastIndex)
+	// Append up to MaxAppendEntries or up to the lastIndex. A peer caught
+	// up in leadershipTransferCatchUp gets a wider batch so the transfer
+	// finishes as fast as possible.
+	maxEntries := r.maxAppendEntries(s)
+	req.Entries = make([]*Log, 0, maxEntries)
+	maxIndex = min(s.nextIndex+uint64(maxEntries)-1, lastIndex)
 	for i := s.nextIndex; i <= maxIndex; i++ {
 		oldLog := new(Log)
 		if err := r.logs.GetLog(i, oldLog); err != nil {
@@ -260,8 +289,12 @@ SEND_SNAP:
 	goto CHECK_MORE
 }
 
-// sendLatestSnapshot is used to send the latest snapshot we have
-// down to our follower
+// sendLatestSnapshot is used to send the latest snapshot we have down
+// to our follower. The snapshot is streamed as a sequence of chunks
+// sized by r.conf.SnapshotChunkSize, each acknowledged by the follower
+// with the highest contiguous byte offset it has durably buffered, so a
+// transport error partway through a multi-GB FSM snapshot only costs
+// the unacknowledged tail instead of the whole transfer.
 func (r *Raft) sendLatestSnapshot(s *followerReplication) (bool, error) {
 	// Get the snapshots
 	snapshots, err := r.snapshots.List()
@@ -284,58 +317,168 @@ func (r *Raft) sendLatestSnapshot(s *followerReplication) (bool, error) {
 	}
 	defer snapshot.Close()
 
-	// Setup the request
-	req := InstallSnapshotRequest{
-		Term:         s.currentTerm,
-		Leader:       r.trans.EncodePeer(r.localAddr),
-		LastLogIndex: meta.Index,
-		LastLogTerm:  meta.Term,
-		Peers:        meta.Peers,
-		Size:         meta.Size,
+	// A new snapshot ID means any resumable state we were tracking for
+	// this peer belonged to a stale transfer; start over from offset 0.
+	if s.snapshotOpID != snapID {
+		s.snapshotOpID = snapID
+		s.snapshotOffset = 0
+		s.highestAckedOffset = 0
 	}
 
-	// Make the call
-	start := time.Now()
-	var resp InstallSnapshotResponse
-	if err := r.trans.InstallSnapshot(s.peer, &req, &resp, snapshot); err != nil {
-		r.logger.Printf("[ERR] raft: Failed to install snapshot %v: %v", snapID, err)
+	// Ask the follower what it has durably buffered for this OpID
+	// already. This covers both a follower restart mid-transfer and a
+	// leader restart that wiped our in-memory snapshotOffset.
+	offset, err := r.queryPeerSnapshotOffset(s, snapID)
+	if err != nil {
+		r.logger.Printf("[ERR] raft: Failed to query snapshot offset from %v: %v", s.peer, err)
 		s.failures++
 		return false, err
 	}
-	metrics.MeasureSince([]string{"raft", "replication", "installSnapshot", s.peer.String()}, start)
+	// The follower is the authority on what it has durably staged: resume
+	// from its offset, not from what we dispatched, since chunks sent but
+	// never acknowledged may never have been staged.
+	s.snapshotOffset = offset
+	s.highestAckedOffset = offset
+	if s.snapshotOffset > 0 {
+		if _, err := snapshot.Seek(s.snapshotOffset, io.SeekStart); err != nil {
+			return false, fmt.Errorf("failed to seek snapshot %v to offset %d: %v", snapID, s.snapshotOffset, err)
+		}
+	}
 
-	// Check for a newer term, stop running
-	if resp.Term > req.Term {
-		r.logger.Printf("[ERR] raft: peer %v has newer term, stopping replication", s.peer)
+	// Pipeline up to MaxInflightSnapshotChunks chunk RPCs at once so a
+	// high-latency link to the peer doesn't serialize the whole transfer
+	// behind a round trip per chunk. The follower is the authority on
+	// how much of the snapshot it has durably staged, so out-of-order
+	// acks are fine: we just track the highest offset any ack reported.
+	inflight := r.conf.MaxInflightSnapshotChunks
+	if inflight < 1 {
+		inflight = 1
+	}
+	sem := make(chan struct{}, inflight)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	stepDown := false
+	chunkSize := r.conf.SnapshotChunkSize
+	for s.snapshotOffset < meta.Size {
+		mu.Lock()
+		stop := firstErr != nil || stepDown
+		mu.Unlock()
+		if stop {
+			break
+		}
+
+		buf := make([]byte, chunkSize)
+		n, rerr := io.ReadFull(snapshot, buf)
+		if rerr != nil && rerr != io.ErrUnexpectedEOF && rerr != io.EOF {
+			wg.Wait()
+			return false, fmt.Errorf("failed to read snapshot %v at offset %d: %v", snapID, s.snapshotOffset, rerr)
+		}
+
+		req := InstallSnapshotRequest{
+			Term:         s.currentTerm,
+			Leader:       r.trans.EncodePeer(r.localAddr),
+			LastLogIndex: meta.Index,
+			LastLogTerm:  meta.Term,
+			Peers:        meta.Peers,
+			Size:         meta.Size,
+			OpID:         snapID,
+			Offset:       s.snapshotOffset,
+			ChunkLen:     uint64(n),
+			IsFinal:      s.snapshotOffset+int64(n) >= meta.Size,
+		}
+		data := buf[:n]
+		s.snapshotOffset += int64(n)
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			var resp InstallSnapshotResponse
+			if err := r.trans.InstallSnapshot(s.peer, &req, &resp, bytes.NewReader(data)); err != nil {
+				r.logger.Printf("[ERR] raft: Failed to install snapshot chunk at offset %d to %v: %v", req.Offset, s.peer, err)
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			metrics.MeasureSince([]string{"raft", "replication", "installSnapshot", s.peer.String()}, start)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if resp.Term > req.Term {
+				r.logger.Printf("[ERR] raft: peer %v has newer term, stopping replication", s.peer)
+				stepDown = true
+				return
+			}
+			if !resp.Success {
+				r.logger.Printf("[WARN] raft: InstallSnapshot chunk at offset %d to %v rejected", req.Offset, s.peer)
+				if firstErr == nil {
+					firstErr = fmt.Errorf("snapshot chunk at offset %d rejected by %v", req.Offset, s.peer)
+				}
+				return
+			}
+			if resp.Offset > s.highestAckedOffset {
+				s.highestAckedOffset = resp.Offset
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stepDown {
 		s.notifyAll(false) // No longer leader
 		asyncNotifyCh(s.stepDown)
 		return true, nil
 	}
-
-	// Update the last contact
 	s.setLastContact()
+	if firstErr != nil {
+		s.failures++
+		return false, firstErr
+	}
+	s.snapshotOffset = s.highestAckedOffset
 
-	// Check for success
-	if resp.Success {
-		// Mark any inflight logs as committed
-		s.inflight.CommitRange(s.matchIndex+1, meta.Index)
+	// Mark any inflight logs as committed
+	s.inflight.CommitRange(s.matchIndex+1, meta.Index)
 
-		// Update the indexes
-		s.matchIndex = meta.Index
-		s.nextIndex = s.matchIndex + 1
+	// Update the indexes
+	s.matchIndex = meta.Index
+	s.nextIndex = s.matchIndex + 1
 
-		// Clear any failures
-		s.failures = 0
+	// Clear any failures and resumable state; the transfer is done
+	s.failures = 0
+	s.snapshotOpID = ""
+	s.snapshotOffset = 0
+	s.highestAckedOffset = 0
 
-		// Notify we are still leader
-		s.notifyAll(true)
-	} else {
-		s.failures++
-		r.logger.Printf("[WARN] raft: InstallSnapshot to %v rejected", s.peer)
-	}
+	// Notify we are still leader
+	s.notifyAll(true)
 	return false, nil
 }
 
+// queryPeerSnapshotOffset asks the follower for the highest contiguous
+// byte offset it has durably staged for opID, without sending any
+// chunk data. A follower seeing this OpID for the first time reports
+// offset 0.
+func (r *Raft) queryPeerSnapshotOffset(s *followerReplication, opID string) (int64, error) {
+	req := InstallSnapshotRequest{
+		Term:   s.currentTerm,
+		Leader: r.trans.EncodePeer(r.localAddr),
+		OpID:   opID,
+		Offset: -1,
+	}
+	var resp InstallSnapshotResponse
+	if err := r.trans.InstallSnapshot(s.peer, &req, &resp, bytes.NewReader(nil)); err != nil {
+		return 0, err
+	}
+	return resp.Offset, nil
+}
+
 // hearbeat is used to periodically invoke AppendEntries on a peer
 // to ensure they don't time out. This is done async of replicate(),
 // since that routine could potentially be blocked on disk IO
@@ -399,6 +542,7 @@ func (r *Raft) pipelineReplicate(s *followerReplication) error {
 	nextIndex := s.nextIndex
 
 	// Send data as available
+	var transfer *leadershipTransferFuture
 	shouldStop := false
 SEND:
 	for !shouldStop {
@@ -410,6 +554,15 @@ SEND:
 				r.pipelineSend(s, pipeline, &nextIndex, maxIndex)
 			}
 			break SEND
+		case future := <-s.transferCh:
+			// leadershipTransferCatchUp drives replicateTo directly and
+			// isn't pipeline-aware, and it updates s.matchIndex/
+			// s.nextIndex with no lock of its own, same as our decoder
+			// goroutine below: stash the future and break out of
+			// pipeline mode so the decoder is torn down first, then run
+			// the catch-up once it's the only thing touching s.
+			transfer = future
+			break SEND
 		case <-s.triggerCh:
 			shouldStop = r.pipelineSend(s, pipeline, &nextIndex, r.getLastLogIndex())
 		case <-randomTimeout(r.conf.CommitTimeout):
@@ -425,6 +578,10 @@ SEND:
 	case <-finishCh:
 	case <-r.shutdownCh:
 	}
+
+	if transfer != nil {
+		r.leadershipTransferCatchUp(s, transfer)
+	}
 	return nil
 }
 
@@ -465,9 +622,12 @@ func (r *Raft) pipelineSend(s *followerReplication, p AppendPipeline, nextIdx *u
 		req.PrevLogTerm = l.Term
 	}
 
-	// Append up to MaxAppendEntries or up to the lastIndex
-	req.Entries = make([]*Log, 0, r.conf.MaxAppendEntries)
-	maxIndex := min(nextIndex+uint64(r.conf.MaxAppendEntries)-1, lastIndex)
+	// Append up to MaxAppendEntries or up to the lastIndex. A peer caught
+	// up in leadershipTransferCatchUp gets a wider batch so the transfer
+	// finishes as fast as possible.
+	maxEntries := r.maxAppendEntries(s)
+	req.Entries = make([]*Log, 0, maxEntries)
+	maxIndex := min(nextIndex+uint64(maxEntries)-1, lastIndex)
 	for i := nextIndex; i <= maxIndex; i++ {
 		oldLog := new(Log)
 		if err := r.logs.GetLog(i, oldLog); err != nil {
@@ -0,0 +1,25 @@
+package raft
+
+import "fmt"
+
+// processRPC dispatches a single inbound RPC, read off r.rpcCh by the
+// run loop, to its handler. The AppendEntries/RequestVote/
+// InstallSnapshot cases are the same dispatch the follower loop has
+// always used; TimeoutNowRequest is added here so a leader's
+// leadershipTransferCatchUp actually produces a state transition on
+// the receiving end instead of the RPC landing in the default case.
+func (r *Raft) processRPC(rpc RPC) {
+	switch cmd := rpc.Command.(type) {
+	case *AppendEntriesRequest:
+		r.appendEntries(rpc, cmd)
+	case *RequestVoteRequest:
+		r.requestVote(rpc, cmd)
+	case *InstallSnapshotRequest:
+		r.processInstallSnapshot(rpc, cmd)
+	case *TimeoutNowRequest:
+		r.processTimeoutNow(rpc, cmd)
+	default:
+		r.logger.Printf("[ERR] raft: Got unexpected command: %#v", rpc.Command)
+		rpc.Respond(nil, fmt.Errorf("unexpected command"))
+	}
+}
@@ -0,0 +1,98 @@
+package raft
+
+import (
+	"fmt"
+	"io"
+)
+
+// processInstallSnapshot is the follower-side handler for a resumable,
+// chunk-acknowledged InstallSnapshot RPC, dispatched from the same
+// *InstallSnapshotRequest case in the RPC loop that used to hand the
+// whole snapshot to the FSM in one call. Now that sendLatestSnapshot
+// ships the snapshot as a sequence of OpID/Offset/ChunkLen/IsFinal
+// chunks, this handler stages each chunk durably via r.snapshotStaging
+// and only restores the FSM once the staged data reaches the
+// snapshot's full size with no gaps, replying with the highest
+// contiguous offset staged for the OpID so the leader knows what it
+// can skip resending.
+func (r *Raft) processInstallSnapshot(rpc RPC, req *InstallSnapshotRequest) {
+	resp := &InstallSnapshotResponse{
+		Term:    r.getCurrentTerm(),
+		Success: false,
+	}
+	var rpcErr error
+	defer func() {
+		rpc.Respond(resp, rpcErr)
+	}()
+
+	if req.Term < r.getCurrentTerm() {
+		r.logger.Printf("[WARN] raft: InstallSnapshot from %v rejected, stale term %d < %d", req.Leader, req.Term, r.getCurrentTerm())
+		return
+	}
+
+	// A pure offset query carries no data: answer from durable staging
+	// state without touching rpc.Reader.
+	if req.Offset < 0 {
+		offset, err := r.snapshotStaging.Offset(req.OpID)
+		if err != nil {
+			rpcErr = fmt.Errorf("failed to query staged offset for op %s: %v", req.OpID, err)
+			return
+		}
+		resp.Success = true
+		resp.Offset = offset
+		return
+	}
+
+	data := make([]byte, req.ChunkLen)
+	if _, err := io.ReadFull(rpc.Reader, data); err != nil {
+		rpcErr = fmt.Errorf("failed to read chunk at offset %d for op %s: %v", req.Offset, req.OpID, err)
+		return
+	}
+
+	offset, err := r.snapshotStaging.WriteChunk(req.OpID, req.Offset, data)
+	if err != nil {
+		rpcErr = fmt.Errorf("failed to stage chunk at offset %d for op %s: %v", req.Offset, req.OpID, err)
+		return
+	}
+	resp.Success = true
+	resp.Offset = offset
+
+	// Don't gate promotion on this particular request being the one
+	// tagged IsFinal: sendLatestSnapshot dispatches chunks over
+	// independent concurrent streams with no ordering guarantee, so the
+	// chunk that closes the last gap to req.Size is frequently not the
+	// IsFinal one. Complete is the single point that decides which of
+	// several racing requests gets to promote.
+	if offset < int64(req.Size) {
+		return
+	}
+	complete, err := r.snapshotStaging.Complete(req.OpID, int64(req.Size))
+	if err != nil {
+		rpcErr = fmt.Errorf("failed to check completion for op %s: %v", req.OpID, err)
+		return
+	}
+	if !complete {
+		return
+	}
+
+	snap, err := r.snapshotStaging.Promote(req.OpID)
+	if err != nil {
+		rpcErr = fmt.Errorf("failed to promote staged snapshot for op %s: %v", req.OpID, err)
+		return
+	}
+	restoreErr := r.fsm.Restore(snap)
+	snap.Close()
+	if restoreErr != nil {
+		rpcErr = fmt.Errorf("failed to restore snapshot for op %s: %v", req.OpID, restoreErr)
+		return
+	}
+	if err := r.snapshotStaging.Discard(req.OpID); err != nil {
+		r.logger.Printf("[WARN] raft: failed to discard staging state for op %s: %v", req.OpID, err)
+	}
+
+	r.setLastSnapshot(req.LastLogIndex, req.LastLogTerm)
+	r.setLastApplied(req.LastLogIndex)
+	if err := r.logs.DeleteRange(r.getFirstLogIndex(), req.LastLogIndex); err != nil {
+		r.logger.Printf("[WARN] raft: failed to compact log through installed snapshot index %d: %v", req.LastLogIndex, err)
+	}
+}
@@ -0,0 +1,239 @@
+package raft
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// snapshotStaging tracks resumable InstallSnapshot transfers on the
+// follower side. Each OpID gets its own file under a staging directory
+// so a chunk written at a given offset survives a follower restart, and
+// is only handed over for restore once every byte up to the snapshot's
+// full size, the IsFinal chunk's included, is staged with no gaps.
+type snapshotStaging struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*stagingFile
+}
+
+type stagingFile struct {
+	f *os.File
+
+	// highestContiguous is the largest offset such that every byte
+	// below it has been durably written; gaps left by out-of-order
+	// chunk delivery are not counted until they're filled in. It is
+	// mirrored to a sidecar marker file on every change: os.File.WriteAt
+	// sparse-extends a file past its current end, so a gap chunk
+	// durably written ahead of an earlier one still missing would make
+	// info.Size() overstate what's truly contiguous after a restart if
+	// we derived it from file size instead.
+	highestContiguous int64
+	pending           map[int64]int64 // offset -> length, for chunks that arrived ahead of a gap
+
+	// completed is set by Complete the first time highestContiguous
+	// reaches the snapshot's full size, so that two chunk RPCs racing to
+	// close the last gap (each over its own independent gRPC stream,
+	// with no ordering between them) can't both decide to promote.
+	completed bool
+}
+
+// newSnapshotStaging prepares dir (creating it if necessary) to hold
+// in-progress snapshot transfers.
+func newSnapshotStaging(dir string) (*snapshotStaging, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot staging dir %q: %v", dir, err)
+	}
+	return &snapshotStaging{
+		dir:   dir,
+		files: make(map[string]*stagingFile),
+	}, nil
+}
+
+func (s *snapshotStaging) path(opID string) string {
+	return filepath.Join(s.dir, opID+".staging")
+}
+
+func (s *snapshotStaging) markPath(opID string) string {
+	return filepath.Join(s.dir, opID+".offset")
+}
+
+// Offset returns the highest contiguous offset durably staged for
+// opID. A never-seen OpID reports offset 0, which also covers the
+// follower-restart case: the staging file and its offset marker are
+// reopened lazily, and the marker (not the staging file's size) is the
+// source of truth for what has already been durably written.
+func (s *snapshotStaging) Offset(opID string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sf, err := s.open(opID)
+	if err != nil {
+		return 0, err
+	}
+	return sf.highestContiguous, nil
+}
+
+// WriteChunk durably stages data at offset for opID and returns the
+// highest contiguous offset staged afterwards. Once that reaches the
+// snapshot's full size, the caller claims completion with Complete,
+// reads the result through Promote, and removes the staging files with
+// Discard.
+func (s *snapshotStaging) WriteChunk(opID string, offset int64, data []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sf, err := s.open(opID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := sf.f.WriteAt(data, offset); err != nil {
+		return 0, fmt.Errorf("failed to stage chunk at offset %d for op %s: %v", offset, opID, err)
+	}
+	if err := sf.f.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync staged chunk for op %s: %v", opID, err)
+	}
+
+	if offset == sf.highestContiguous {
+		sf.highestContiguous += int64(len(data))
+		// Absorb any chunks that arrived earlier but left a gap before
+		// this one; now that the gap is filled they may be contiguous.
+		for {
+			length, ok := sf.pending[sf.highestContiguous]
+			if !ok {
+				break
+			}
+			delete(sf.pending, sf.highestContiguous)
+			sf.highestContiguous += length
+		}
+	} else if offset > sf.highestContiguous {
+		sf.pending[offset] = int64(len(data))
+	}
+
+	if err := s.writeOffsetMarker(opID, sf.highestContiguous); err != nil {
+		return 0, err
+	}
+	return sf.highestContiguous, nil
+}
+
+// writeOffsetMarker durably persists offset as the source of truth for
+// opID's highestContiguous, independent of the staging file's size.
+func (s *snapshotStaging) writeOffsetMarker(opID string, offset int64) error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(offset))
+
+	f, err := os.OpenFile(s.markPath(opID), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open offset marker for op %s: %v", opID, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf[:]); err != nil {
+		return fmt.Errorf("failed to write offset marker for op %s: %v", opID, err)
+	}
+	return f.Sync()
+}
+
+// readOffsetMarker returns the durably persisted highestContiguous for
+// opID, or 0 if no marker has been written yet (a fresh OpID).
+func (s *snapshotStaging) readOffsetMarker(opID string) (int64, error) {
+	buf, err := os.ReadFile(s.markPath(opID))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read offset marker for op %s: %v", opID, err)
+	}
+	if len(buf) != 8 {
+		return 0, fmt.Errorf("corrupt offset marker for op %s: %d bytes", opID, len(buf))
+	}
+	return int64(binary.BigEndian.Uint64(buf)), nil
+}
+
+// Complete reports whether this call is the first to observe that
+// opID has durably staged at least size contiguous bytes, atomically
+// claiming that completion. A caller that wrote the chunk which closed
+// the last gap still needs to check this before promoting: chunks for
+// the same OpID can arrive over independent, concurrently dispatched
+// RPCs in any order, so more than one of them may observe
+// highestContiguous >= size, and only one is allowed to act on it.
+func (s *snapshotStaging) Complete(opID string, size int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sf, err := s.open(opID)
+	if err != nil {
+		return false, err
+	}
+	if sf.completed || sf.highestContiguous < size {
+		return false, nil
+	}
+	sf.completed = true
+	return true, nil
+}
+
+// Promote closes the staging file for opID and returns a reader over
+// the complete, reassembled snapshot, ready to pass to the
+// SnapshotStore's restore path. Callers must only call this once the
+// reported offset reaches the snapshot's full size.
+func (s *snapshotStaging) Promote(opID string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	sf, ok := s.files[opID]
+	delete(s.files, opID)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no staged snapshot for op %s", opID)
+	}
+	if err := sf.f.Close(); err != nil {
+		return nil, err
+	}
+	return os.Open(s.path(opID))
+}
+
+// Discard removes the staging file for opID, e.g. after a successful
+// Promote or an abandoned transfer.
+func (s *snapshotStaging) Discard(opID string) error {
+	s.mu.Lock()
+	sf, ok := s.files[opID]
+	delete(s.files, opID)
+	s.mu.Unlock()
+
+	if ok {
+		sf.f.Close()
+	}
+	if err := os.Remove(s.path(opID)); err != nil {
+		return err
+	}
+	if err := os.Remove(s.markPath(opID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *snapshotStaging) open(opID string) (*stagingFile, error) {
+	if sf, ok := s.files[opID]; ok {
+		return sf, nil
+	}
+
+	f, err := os.OpenFile(s.path(opID), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open staging file for op %s: %v", opID, err)
+	}
+	highestContiguous, err := s.readOffsetMarker(opID)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	sf := &stagingFile{
+		f:                 f,
+		highestContiguous: highestContiguous,
+		pending:           make(map[int64]int64),
+	}
+	s.files[opID] = sf
+	return sf, nil
+}
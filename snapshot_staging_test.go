@@ -0,0 +1,174 @@
+package raft
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotStaging_ContiguousWrites(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging: %v", err)
+	}
+
+	if _, err := s.WriteChunk("op-1", 0, []byte("hello ")); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	offset, err := s.WriteChunk("op-1", 6, []byte("world"))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if offset != 11 {
+		t.Fatalf("offset = %d, want 11", offset)
+	}
+
+	rc, err := s.Promote("op-1")
+	if err != nil {
+		t.Fatalf("Promote: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Fatalf("promoted data = %q, want %q", data, "hello world")
+	}
+}
+
+func TestSnapshotStaging_OutOfOrderGapFilled(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging: %v", err)
+	}
+
+	// Chunk 2 arrives before chunk 1: it must not be counted as
+	// contiguous until the gap at [0,5) is filled in.
+	offset, err := s.WriteChunk("op-1", 5, []byte("world"))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if offset != 0 {
+		t.Fatalf("offset after out-of-order chunk = %d, want 0", offset)
+	}
+
+	offset, err = s.WriteChunk("op-1", 0, []byte("hello"))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if offset != 10 {
+		t.Fatalf("offset after gap filled = %d, want 10", offset)
+	}
+}
+
+// TestSnapshotStaging_RestartDoesNotOverstateOffset simulates a
+// follower restart after an out-of-order (gap) chunk was durably
+// written but the earlier bytes never arrived. A restart must report
+// the real (smaller) highestContiguous, not the staging file's size,
+// which os.File.WriteAt would have sparse-extended past the gap.
+func TestSnapshotStaging_RestartDoesNotOverstateOffset(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging: %v", err)
+	}
+
+	if _, err := s.WriteChunk("op-1", 100, []byte("tail chunk")); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+
+	// Simulate a restart: a fresh snapshotStaging pointed at the same
+	// directory, with no in-memory state carried over.
+	s2, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging after restart: %v", err)
+	}
+	offset, err := s2.Offset("op-1")
+	if err != nil {
+		t.Fatalf("Offset: %v", err)
+	}
+	if offset != 0 {
+		t.Fatalf("offset after restart = %d, want 0 (the gap chunk is not contiguous)", offset)
+	}
+}
+
+// TestSnapshotStaging_CompleteClaimsOnlyOnce simulates the out-of-order
+// concurrent chunk delivery processInstallSnapshot has to cope with: the
+// chunk that closes the last gap to the snapshot's full size is not
+// necessarily the one tagged IsFinal, and several chunk handlers can
+// observe a completed offset at once. Complete must let exactly one of
+// them claim the completion, regardless of call order.
+func TestSnapshotStaging_CompleteClaimsOnlyOnce(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging: %v", err)
+	}
+
+	// Chunk 2 (the IsFinal one) lands before chunk 1, closing the gap.
+	if _, err := s.WriteChunk("op-1", 5, []byte("world")); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	offset, err := s.WriteChunk("op-1", 0, []byte("hello"))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if offset != 10 {
+		t.Fatalf("offset after gap filled = %d, want 10", offset)
+	}
+
+	const racers = 8
+	var wg sync.WaitGroup
+	claims := make([]bool, racers)
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			complete, err := s.Complete("op-1", 10)
+			if err != nil {
+				t.Errorf("Complete: %v", err)
+			}
+			claims[i] = complete
+		}(i)
+	}
+	wg.Wait()
+
+	claimed := 0
+	for _, c := range claims {
+		if c {
+			claimed++
+		}
+	}
+	if claimed != 1 {
+		t.Fatalf("expected exactly one racer to claim completion, got %d", claimed)
+	}
+}
+
+func TestSnapshotStaging_DiscardRemovesMarker(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging: %v", err)
+	}
+	if _, err := s.WriteChunk("op-1", 0, []byte("data")); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if err := s.Discard("op-1"); err != nil {
+		t.Fatalf("Discard: %v", err)
+	}
+
+	s2, err := newSnapshotStaging(dir)
+	if err != nil {
+		t.Fatalf("newSnapshotStaging: %v", err)
+	}
+	offset, err := s2.Offset("op-1")
+	if err != nil {
+		t.Fatalf("Offset: %v", err)
+	}
+	if offset != 0 {
+		t.Fatalf("offset after discard = %d, want 0", offset)
+	}
+}
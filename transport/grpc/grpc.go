@@ -0,0 +1,279 @@
+// Package grpc implements the raft.Transport interface on top of a gRPC
+// bidirectional stream, so that Raft RPCs can be multiplexed onto an
+// existing gRPC server and reuse its TLS/mTLS setup and interceptors
+// instead of requiring a dedicated TCP listener.
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/mauri870/raft"
+	"github.com/mauri870/raft/transport/grpc/raftgrpc"
+)
+
+// Transport implements raft.Transport by dialing peers with a gRPC
+// client and serving raftgrpc.RaftTransportServer on behalf of the
+// caller's own *grpc.Server. Unlike the net transport, it does not own
+// a listener: callers register it on a server they already run so Raft
+// traffic shares that server's TLS and interceptor chain.
+type Transport struct {
+	localAddr net.Addr
+	consumer  chan raft.RPC
+
+	heartbeatFn   func(raft.RPC)
+	heartbeatLock sync.Mutex
+
+	connLock sync.Mutex
+	conns    map[string]*grpc.ClientConn
+
+	dialOptions []grpc.DialOption
+}
+
+// NewTransport creates a Transport bound to localAddr. dialOpts are
+// passed to grpc.Dial for every outbound peer connection, which is how
+// callers configure TLS, keepalive, or client interceptors.
+func NewTransport(localAddr net.Addr, dialOpts ...grpc.DialOption) *Transport {
+	return &Transport{
+		localAddr:   localAddr,
+		consumer:    make(chan raft.RPC),
+		conns:       make(map[string]*grpc.ClientConn),
+		dialOptions: dialOpts,
+	}
+}
+
+// Register adds the Transport's RaftTransportServer implementation to
+// s. Call this on the same *grpc.Server the application already serves
+// other RPCs on.
+func (t *Transport) Register(s *grpc.Server) {
+	raftgrpc.RegisterRaftTransportServer(s, (*server)(t))
+}
+
+func (t *Transport) Consumer() <-chan raft.RPC {
+	return t.consumer
+}
+
+func (t *Transport) LocalAddr() net.Addr {
+	return t.localAddr
+}
+
+func (t *Transport) EncodePeer(addr net.Addr) []byte {
+	return []byte(addr.String())
+}
+
+func (t *Transport) DecodePeer(buf []byte) net.Addr {
+	return raftAddr(string(buf))
+}
+
+func (t *Transport) SetHeartbeatHandler(cb func(rpc raft.RPC)) {
+	t.heartbeatLock.Lock()
+	defer t.heartbeatLock.Unlock()
+	t.heartbeatFn = cb
+}
+
+func (t *Transport) conn(target net.Addr) (*grpc.ClientConn, error) {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+
+	addr := target.String()
+	if cc, ok := t.conns[addr]; ok {
+		return cc, nil
+	}
+	cc, err := grpc.Dial(addr, t.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	t.conns[addr] = cc
+	return cc, nil
+}
+
+func (t *Transport) client(target net.Addr) (raftgrpc.RaftTransportClient, error) {
+	cc, err := t.conn(target)
+	if err != nil {
+		return nil, err
+	}
+	return raftgrpc.NewRaftTransportClient(cc), nil
+}
+
+func (t *Transport) AppendEntries(target net.Addr, args *raft.AppendEntriesRequest, resp *raft.AppendEntriesResponse) error {
+	c, err := t.client(target)
+	if err != nil {
+		return err
+	}
+	out, err := c.AppendEntries(context.Background(), encodeAppendEntriesRequest(args))
+	if err != nil {
+		return err
+	}
+	decodeAppendEntriesResponse(out, resp)
+	return nil
+}
+
+func (t *Transport) RequestVote(target net.Addr, args *raft.RequestVoteRequest, resp *raft.RequestVoteResponse) error {
+	c, err := t.client(target)
+	if err != nil {
+		return err
+	}
+	out, err := c.RequestVote(context.Background(), &raftgrpc.RequestVoteRequest{
+		Term:         args.Term,
+		Candidate:    args.Candidate,
+		LastLogIndex: args.LastLogIndex,
+		LastLogTerm:  args.LastLogTerm,
+	})
+	if err != nil {
+		return err
+	}
+	resp.Term = out.Term
+	resp.Peer = out.Peer
+	resp.Granted = out.Granted
+	return nil
+}
+
+// TimeoutNow tells target to skip its randomized election timeout and
+// start an election immediately, as the last step of a
+// raft.Raft.LeadershipTransfer.
+func (t *Transport) TimeoutNow(target net.Addr, args *raft.TimeoutNowRequest, resp *raft.TimeoutNowResponse) error {
+	c, err := t.client(target)
+	if err != nil {
+		return err
+	}
+	out, err := c.TimeoutNow(context.Background(), &raftgrpc.TimeoutNowRequest{
+		Term:   args.Term,
+		Leader: args.Leader,
+	})
+	if err != nil {
+		return err
+	}
+	resp.Term = out.Term
+	resp.Success = out.Success
+	return nil
+}
+
+// InstallSnapshot sends a single chunk of a snapshot transfer, or a
+// pure offset query when args.Offset is negative. Raft's own
+// sendLatestSnapshot already splits the full snapshot into
+// SnapshotChunkSize chunks and calls this once per chunk, so each call
+// here opens the client-streaming RPC, sends the one frame it was
+// given, and closes the stream to get the ack.
+func (t *Transport) InstallSnapshot(target net.Addr, args *raft.InstallSnapshotRequest, resp *raft.InstallSnapshotResponse, data io.Reader) error {
+	c, err := t.client(target)
+	if err != nil {
+		return err
+	}
+	stream, err := c.InstallSnapshot(context.Background())
+	if err != nil {
+		return err
+	}
+
+	chunk, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	req := &raftgrpc.InstallSnapshotRequest{
+		Term:         args.Term,
+		Leader:       args.Leader,
+		LastLogIndex: args.LastLogIndex,
+		LastLogTerm:  args.LastLogTerm,
+		Peers:        args.Peers,
+		Size:         uint64(args.Size),
+		Data:         chunk,
+		OpId:         args.OpID,
+		Offset:       args.Offset,
+		ChunkLen:     args.ChunkLen,
+		IsFinal:      args.IsFinal,
+	}
+	if err := stream.Send(req); err != nil {
+		return err
+	}
+
+	out, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	resp.Term = out.Term
+	resp.Success = out.Success
+	resp.Offset = out.Offset
+	return nil
+}
+
+// AppendEntriesPipeline opens the bidirectional AppendEntriesPipeline
+// stream and returns a pipeline backed by it; the server replies on the
+// same stream in order, so no extra sequencing is required on top of
+// gRPC's own stream ordering guarantee.
+func (t *Transport) AppendEntriesPipeline(target net.Addr) (raft.AppendPipeline, error) {
+	c, err := t.client(target)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.AppendEntriesPipeline(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return newPipeline(target, stream), nil
+}
+
+type raftAddr string
+
+func (a raftAddr) Network() string { return "tcp" }
+func (a raftAddr) String() string  { return string(a) }
+
+func encodeAppendEntriesRequest(args *raft.AppendEntriesRequest) *raftgrpc.AppendEntriesRequest {
+	entries := make([]*raftgrpc.Log, len(args.Entries))
+	for i, e := range args.Entries {
+		entries[i] = &raftgrpc.Log{
+			Index:      e.Index,
+			Term:       e.Term,
+			Type:       uint32(e.Type),
+			Data:       e.Data,
+			Extensions: e.Extensions,
+		}
+	}
+	return &raftgrpc.AppendEntriesRequest{
+		Term:              args.Term,
+		Leader:            args.Leader,
+		PrevLogEntry:      args.PrevLogEntry,
+		PrevLogTerm:       args.PrevLogTerm,
+		Entries:           entries,
+		LeaderCommitIndex: args.LeaderCommitIndex,
+	}
+}
+
+func decodeAppendEntriesRequest(in *raftgrpc.AppendEntriesRequest) *raft.AppendEntriesRequest {
+	entries := make([]*raft.Log, len(in.Entries))
+	for i, e := range in.Entries {
+		entries[i] = &raft.Log{
+			Index:      e.Index,
+			Term:       e.Term,
+			Type:       raft.LogType(e.Type),
+			Data:       e.Data,
+			Extensions: e.Extensions,
+		}
+	}
+	return &raft.AppendEntriesRequest{
+		Term:              in.Term,
+		Leader:            in.Leader,
+		PrevLogEntry:      in.PrevLogEntry,
+		PrevLogTerm:       in.PrevLogTerm,
+		Entries:           entries,
+		LeaderCommitIndex: in.LeaderCommitIndex,
+	}
+}
+
+func decodeAppendEntriesResponse(in *raftgrpc.AppendEntriesResponse, out *raft.AppendEntriesResponse) {
+	out.Term = in.Term
+	out.LastLog = in.LastLog
+	out.Success = in.Success
+	out.NoRetryBackoff = in.NoRetryBackoff
+}
+
+func encodeAppendEntriesResponse(resp *raft.AppendEntriesResponse) *raftgrpc.AppendEntriesResponse {
+	return &raftgrpc.AppendEntriesResponse{
+		Term:           resp.Term,
+		LastLog:        resp.LastLog,
+		Success:        resp.Success,
+		NoRetryBackoff: resp.NoRetryBackoff,
+	}
+}
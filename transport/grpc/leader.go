@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mauri870/raft"
+)
+
+// NotLeaderError is returned by LeaderInterceptor when the local node
+// does not believe it is the leader. The interceptor sends it to the
+// client only as the status message, which names the address to
+// redirect to; the status carries no details. Leader is empty if no
+// leader is known yet.
+type NotLeaderError struct {
+	Leader string
+}
+
+func (e *NotLeaderError) Error() string {
+	if e.Leader == "" {
+		return "not the leader, and no leader is known"
+	}
+	return "not the leader, try " + e.Leader
+}
+
+// LeaderInterceptor builds a unary server interceptor that rejects
+// application RPCs when r is not the current leader, returning a
+// FailedPrecondition status carrying the current leader's address so a
+// gRPC client can redirect without the caller's code knowing anything
+// about Raft. It is meant for the application's own service methods,
+// not the Transport's RaftTransport service, which must always be
+// reachable regardless of leadership.
+func LeaderInterceptor(r *raft.Raft) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r.State() != raft.Leader {
+			leader := ""
+			if addr := r.Leader(); addr != nil {
+				leader = addr.String()
+			}
+			err := &NotLeaderError{Leader: leader}
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
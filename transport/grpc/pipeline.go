@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mauri870/raft"
+	"github.com/mauri870/raft/transport/grpc/raftgrpc"
+)
+
+// pipeline implements raft.AppendPipeline over the bidirectional
+// AppendEntriesPipeline stream: sends go out as fast as the caller
+// issues them and a background goroutine matches each response back to
+// its request in the order gRPC guarantees they arrive.
+type pipeline struct {
+	target net.Addr
+	stream raftgrpc.RaftTransport_AppendEntriesPipelineClient
+
+	doneCh chan raft.AppendFuture
+
+	inflightLock sync.Mutex
+	inflight     []*appendFuture
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+func newPipeline(target net.Addr, stream raftgrpc.RaftTransport_AppendEntriesPipelineClient) *pipeline {
+	p := &pipeline{
+		target:     target,
+		stream:     stream,
+		doneCh:     make(chan raft.AppendFuture, 128),
+		shutdownCh: make(chan struct{}),
+	}
+	go p.decodeResponses()
+	return p
+}
+
+func (p *pipeline) decodeResponses() {
+	for {
+		in, err := p.stream.Recv()
+		if err != nil {
+			p.abort(err)
+			return
+		}
+
+		p.inflightLock.Lock()
+		if len(p.inflight) == 0 {
+			p.inflightLock.Unlock()
+			continue
+		}
+		future := p.inflight[0]
+		p.inflight = p.inflight[1:]
+		p.inflightLock.Unlock()
+
+		decodeAppendEntriesResponse(in, future.resp)
+		close(future.done)
+
+		select {
+		case p.doneCh <- future:
+		case <-p.shutdownCh:
+			return
+		}
+	}
+}
+
+// abort resolves every future still awaiting a response with err, so a
+// broken stream surfaces as an error on appendFuture.Error() instead of
+// blocking those callers forever.
+func (p *pipeline) abort(err error) {
+	p.inflightLock.Lock()
+	pending := p.inflight
+	p.inflight = nil
+	p.inflightLock.Unlock()
+
+	for _, future := range pending {
+		future.err = err
+		close(future.done)
+	}
+}
+
+func (p *pipeline) AppendEntries(req *raft.AppendEntriesRequest, resp *raft.AppendEntriesResponse) (raft.AppendFuture, error) {
+	future := &appendFuture{
+		start: time.Now(),
+		req:   req,
+		resp:  resp,
+		done:  make(chan struct{}),
+	}
+
+	p.inflightLock.Lock()
+	p.inflight = append(p.inflight, future)
+	p.inflightLock.Unlock()
+
+	if err := p.stream.Send(encodeAppendEntriesRequest(req)); err != nil {
+		p.inflightLock.Lock()
+		for i, f := range p.inflight {
+			if f == future {
+				p.inflight = append(p.inflight[:i], p.inflight[i+1:]...)
+				break
+			}
+		}
+		p.inflightLock.Unlock()
+		return nil, err
+	}
+	return future, nil
+}
+
+func (p *pipeline) Consumer() <-chan raft.AppendFuture {
+	return p.doneCh
+}
+
+func (p *pipeline) Close() error {
+	p.shutdownOnce.Do(func() { close(p.shutdownCh) })
+	return p.stream.CloseSend()
+}
+
+// appendFuture implements raft.AppendFuture for a single in-flight
+// pipelined AppendEntries call.
+type appendFuture struct {
+	start time.Time
+	req   *raft.AppendEntriesRequest
+	resp  *raft.AppendEntriesResponse
+	done  chan struct{}
+	err   error
+}
+
+func (f *appendFuture) Error() error {
+	<-f.done
+	return f.err
+}
+
+func (f *appendFuture) Response() *raft.AppendEntriesResponse {
+	return f.resp
+}
+
+func (f *appendFuture) Request() *raft.AppendEntriesRequest {
+	return f.req
+}
+
+func (f *appendFuture) Start() time.Time {
+	return f.start
+}
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/mauri870/raft"
+	"github.com/mauri870/raft/transport/grpc/raftgrpc"
+)
+
+// fakePipelineStream implements raftgrpc.RaftTransport_AppendEntriesPipelineClient
+// against an in-memory response/error queue, so pipeline's send/recv
+// bookkeeping can be tested without a real gRPC connection.
+type fakePipelineStream struct {
+	grpc.ClientStream
+
+	mu        sync.Mutex
+	sendErrs  []error
+	responses chan *raftgrpc.AppendEntriesResponse
+	recvErr   error
+}
+
+func newFakePipelineStream() *fakePipelineStream {
+	return &fakePipelineStream{
+		responses: make(chan *raftgrpc.AppendEntriesResponse, 8),
+	}
+}
+
+func (f *fakePipelineStream) Send(*raftgrpc.AppendEntriesRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.sendErrs) > 0 {
+		err := f.sendErrs[0]
+		f.sendErrs = f.sendErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakePipelineStream) Recv() (*raftgrpc.AppendEntriesResponse, error) {
+	resp, ok := <-f.responses
+	if !ok {
+		f.mu.Lock()
+		err := f.recvErr
+		f.mu.Unlock()
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (f *fakePipelineStream) CloseSend() error { return nil }
+
+func (f *fakePipelineStream) breakWith(err error) {
+	f.mu.Lock()
+	f.recvErr = err
+	f.mu.Unlock()
+	close(f.responses)
+}
+
+type fakeAddr string
+
+func (a fakeAddr) Network() string { return "tcp" }
+func (a fakeAddr) String() string  { return string(a) }
+
+func waitForFuture(t *testing.T, f raft.AppendFuture) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- f.Error() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("appendFuture.Error() did not return; future was left stuck")
+		return nil
+	}
+}
+
+func TestPipeline_SendFailureDoesNotPoisonCorrelation(t *testing.T) {
+	stream := newFakePipelineStream()
+	stream.sendErrs = []error{errors.New("transient send failure"), nil}
+	p := newPipeline(fakeAddr("peer"), stream)
+	defer p.Close()
+
+	// The first AppendEntries fails to send; it must not stay in
+	// inflight, or the next real response would be matched to it.
+	if _, err := p.AppendEntries(&raft.AppendEntriesRequest{Term: 1}, new(raft.AppendEntriesResponse)); err == nil {
+		t.Fatal("expected send error")
+	}
+
+	resp := new(raft.AppendEntriesResponse)
+	future, err := p.AppendEntries(&raft.AppendEntriesRequest{Term: 1}, resp)
+	if err != nil {
+		t.Fatalf("AppendEntries: %v", err)
+	}
+	stream.responses <- &raftgrpc.AppendEntriesResponse{Term: 1, Success: true, LastLog: 42}
+
+	if err := waitForFuture(t, future); err != nil {
+		t.Fatalf("future.Error() = %v", err)
+	}
+	if !resp.Success || resp.LastLog != 42 {
+		t.Fatalf("response was not correlated to the right request: %+v", resp)
+	}
+}
+
+func TestPipeline_StreamBreakUnblocksPendingFutures(t *testing.T) {
+	stream := newFakePipelineStream()
+	p := newPipeline(fakeAddr("peer"), stream)
+	defer p.Close()
+
+	future, err := p.AppendEntries(&raft.AppendEntriesRequest{Term: 1}, new(raft.AppendEntriesResponse))
+	if err != nil {
+		t.Fatalf("AppendEntries: %v", err)
+	}
+
+	stream.breakWith(errors.New("connection reset"))
+
+	if err := waitForFuture(t, future); err == nil {
+		t.Fatal("expected future to surface the stream error, got nil")
+	}
+}
+
+var _ net.Addr = fakeAddr("")
@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/mauri870/raft"
+	"github.com/mauri870/raft/transport/grpc/raftgrpc"
+)
+
+// server adapts a *Transport to raftgrpc.RaftTransportServer. It is a
+// distinct named type purely so the RPC handlers below don't leak onto
+// the public Transport API that callers dial against.
+type server Transport
+
+func (s *server) dispatch(command interface{}) (interface{}, error) {
+	ch := make(chan raft.RPCResponse, 1)
+	(*Transport)(s).consumer <- raft.RPC{
+		Command:  command,
+		RespChan: ch,
+	}
+	rpcResp := <-ch
+	return rpcResp.Response, rpcResp.Error
+}
+
+func (s *server) AppendEntries(ctx context.Context, in *raftgrpc.AppendEntriesRequest) (*raftgrpc.AppendEntriesResponse, error) {
+	req := decodeAppendEntriesRequest(in)
+	out, err := s.dispatch(req)
+	if err != nil {
+		return nil, err
+	}
+	return encodeAppendEntriesResponse(out.(*raft.AppendEntriesResponse)), nil
+}
+
+func (s *server) RequestVote(ctx context.Context, in *raftgrpc.RequestVoteRequest) (*raftgrpc.RequestVoteResponse, error) {
+	req := &raft.RequestVoteRequest{
+		Term:         in.Term,
+		Candidate:    in.Candidate,
+		LastLogIndex: in.LastLogIndex,
+		LastLogTerm:  in.LastLogTerm,
+	}
+	out, err := s.dispatch(req)
+	if err != nil {
+		return nil, err
+	}
+	resp := out.(*raft.RequestVoteResponse)
+	return &raftgrpc.RequestVoteResponse{
+		Term:    resp.Term,
+		Peer:    resp.Peer,
+		Granted: resp.Granted,
+	}, nil
+}
+
+func (s *server) TimeoutNow(ctx context.Context, in *raftgrpc.TimeoutNowRequest) (*raftgrpc.TimeoutNowResponse, error) {
+	req := &raft.TimeoutNowRequest{
+		Term:   in.Term,
+		Leader: in.Leader,
+	}
+	out, err := s.dispatch(req)
+	if err != nil {
+		return nil, err
+	}
+	resp := out.(*raft.TimeoutNowResponse)
+	return &raftgrpc.TimeoutNowResponse{
+		Term:    resp.Term,
+		Success: resp.Success,
+	}, nil
+}
+
+// AppendEntriesPipeline serves the bidirectional stream by decoding each
+// request, dispatching it to the consumer like any other RPC, and
+// writing the response back before reading the next request. gRPC's
+// stream already preserves ordering, so the decode loop needs no
+// sequence numbers of its own.
+func (s *server) AppendEntriesPipeline(stream raftgrpc.RaftTransport_AppendEntriesPipelineServer) error {
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		out, err := s.dispatch(decodeAppendEntriesRequest(in))
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(encodeAppendEntriesResponse(out.(*raft.AppendEntriesResponse))); err != nil {
+			return err
+		}
+	}
+}
+
+// InstallSnapshot handles a single chunk of a resumable snapshot
+// transfer: raft.Raft.sendLatestSnapshot calls Transport.InstallSnapshot
+// once per chunk, so one client-streaming RPC here always carries
+// exactly one frame. A request with a negative Offset carries no data
+// and is a pure query for the highest contiguous offset this follower
+// has staged for OpId, which the consumer's FSM/SnapshotStore plumbing
+// is expected to answer without treating it as real chunk data.
+func (s *server) InstallSnapshot(stream raftgrpc.RaftTransport_InstallSnapshotServer) error {
+	in, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	req := &raft.InstallSnapshotRequest{
+		Term:         in.Term,
+		Leader:       in.Leader,
+		LastLogIndex: in.LastLogIndex,
+		LastLogTerm:  in.LastLogTerm,
+		Peers:        in.Peers,
+		Size:         int64(in.Size),
+		OpID:         in.OpId,
+		Offset:       in.Offset,
+		ChunkLen:     in.ChunkLen,
+		IsFinal:      in.IsFinal,
+	}
+
+	ch := make(chan raft.RPCResponse, 1)
+	(*Transport)(s).consumer <- raft.RPC{
+		Command:  req,
+		Reader:   bytes.NewReader(in.Data),
+		RespChan: ch,
+	}
+	rpcResp := <-ch
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	resp := rpcResp.Response.(*raft.InstallSnapshotResponse)
+	return stream.SendAndClose(&raftgrpc.InstallSnapshotResponse{
+		Term:    resp.Term,
+		Success: resp.Success,
+		Offset:  resp.Offset,
+	})
+}